@@ -0,0 +1,119 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Client talks to a Portainer instance's HTTP API.
+type Client struct {
+	BaseURL    string
+	AuthToken  string
+	httpClient *http.Client
+}
+
+// NewClient returns a Client that authenticates requests to baseURL with
+// authToken.
+func NewClient(baseURL string, authToken string) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		AuthToken:  authToken,
+		httpClient: &http.Client{},
+	}
+}
+
+// Marker error types for the HTTP status codes Portainer uses to signal a
+// category of failure. They're unexported and carry no methods beyond the
+// marker one, so they satisfy common's NotFound/Conflict/Unauthorized/
+// Forbidden interfaces structurally without this package importing common
+// (which itself imports client).
+type (
+	notFoundError     struct{ error }
+	conflictError     struct{ error }
+	unauthorizedError struct{ error }
+	forbiddenError    struct{ error }
+)
+
+func (notFoundError) NotFound()         {}
+func (conflictError) Conflict()         {}
+func (unauthorizedError) Unauthorized() {}
+func (forbiddenError) Forbidden()       {}
+
+// classifyStatus wraps err in the marker type matching a Portainer API
+// error response's HTTP status code, if any.
+func classifyStatus(statusCode int, err error) error {
+	switch statusCode {
+	case http.StatusNotFound:
+		return notFoundError{err}
+	case http.StatusConflict:
+		return conflictError{err}
+	case http.StatusUnauthorized:
+		return unauthorizedError{err}
+	case http.StatusForbidden:
+		return forbiddenError{err}
+	default:
+		return err
+	}
+}
+
+// DoJSONWithToken sends a Portainer API request to path, JSON-encoding body
+// (if non-nil) as the request payload and JSON-decoding the response into
+// out (if non-nil). Any extra headers are merged in after Authorization and
+// Content-Type are set. Responses with a 4xx/5xx status are turned into an
+// error classified by classifyStatus, so callers can tell a 404 apart from
+// a 409 or 401/403 with IsNotFound/IsConflict/IsUnauthorized/IsForbidden.
+func (c *Client) DoJSONWithToken(path string, method string, headers http.Header, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "encoding request body")
+		}
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/api/%s", c.BaseURL, path), bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "building request")
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.AuthToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, values := range headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "sending request")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading response body")
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return classifyStatus(resp.StatusCode, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(respBody)))
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return errors.Wrap(err, "decoding response body")
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// ResourceType identifies the kind of resource a Portainer access control
+// applies to.
+type ResourceType string
+
+// Resource types that can carry a Portainer access control.
+const (
+	ResourceContainer ResourceType = "container"
+	ResourceService   ResourceType = "service"
+	ResourceVolume    ResourceType = "volume"
+	ResourceNetwork   ResourceType = "network"
+	ResourceConfig    ResourceType = "config"
+	ResourceSecret    ResourceType = "secret"
+	ResourceStack     ResourceType = "stack"
+)
+
+// AccessControlSpec describes who may access a resource.
+type AccessControlSpec struct {
+	AdministratorsOnly bool
+	Public             bool
+	Users              []portainer.UserID
+	Teams              []portainer.TeamID
+}
+
+type resourceControlCreateRequest struct {
+	ResourceID         string
+	Type               ResourceType
+	AdministratorsOnly bool
+	Public             bool
+	Users              []portainer.UserID
+	Teams              []portainer.TeamID
+}
+
+// ResourceControlCreate creates a new Portainer access control over a
+// resource.
+func (c *Client) ResourceControlCreate(resourceID string, resourceType ResourceType, spec AccessControlSpec) (resourceControl portainer.ResourceControl, err error) {
+	err = c.DoJSONWithToken("resource_controls", http.MethodPost, http.Header{}, resourceControlCreateRequest{
+		ResourceID:         resourceID,
+		Type:               resourceType,
+		AdministratorsOnly: spec.AdministratorsOnly,
+		Public:             spec.Public,
+		Users:              spec.Users,
+		Teams:              spec.Teams,
+	}, &resourceControl)
+	return
+}
+
+// ResourceControlUpdate updates an existing Portainer access control.
+func (c *Client) ResourceControlUpdate(resourceControlID portainer.ResourceControlID, spec AccessControlSpec) (resourceControl portainer.ResourceControl, err error) {
+	err = c.DoJSONWithToken(fmt.Sprintf("resource_controls/%d", resourceControlID), http.MethodPut, http.Header{}, spec, &resourceControl)
+	return
+}
+
+// ResourceControlDelete deletes a Portainer access control.
+func (c *Client) ResourceControlDelete(resourceControlID portainer.ResourceControlID) (err error) {
+	err = c.DoJSONWithToken(fmt.Sprintf("resource_controls/%d", resourceControlID), http.MethodDelete, http.Header{}, nil, nil)
+	return
+}
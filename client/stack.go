@@ -0,0 +1,119 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// StackType identifies a stack's orchestrator.
+type StackType int
+
+// Known stack types.
+const (
+	StackTypeSwarm StackType = iota + 1
+	StackTypeCompose
+	StackTypeKubernetes
+)
+
+// StackListFilter narrows down the stacks returned by StackList. It is
+// JSON-encoded as-is into the "filters" query param, mirroring Portainer's
+// own stackListOperationFilters, which only honors these four fields —
+// there is no server-side filtering by name, type or label.
+type StackListFilter struct {
+	SwarmID               string               `json:",omitempty"`
+	Namespace             string               `json:",omitempty"`
+	EndpointID            portainer.EndpointID `json:",omitempty"`
+	IncludeOrphanedStacks bool                 `json:",omitempty"`
+}
+
+// StackListOptions configures StackList.
+type StackListOptions struct {
+	Filter StackListFilter
+}
+
+// StackList returns the stacks matching options. Filtering happens
+// server-side: options.Filter is JSON-encoded into the "filters" query
+// param rather than applied by looping over every stack in Go. Anything
+// beyond SwarmID/Namespace/EndpointID/IncludeOrphanedStacks (e.g. name or
+// label matching) must be filtered by the caller.
+func (c *Client) StackList(options StackListOptions) (stacks []portainer.Stack, err error) {
+	encodedFilter, err := json.Marshal(options.Filter)
+	if err != nil {
+		return
+	}
+
+	query := url.Values{}
+	query.Set("filters", string(encodedFilter))
+
+	err = c.DoJSONWithToken(fmt.Sprintf("stacks?%s", query.Encode()), http.MethodGet, http.Header{}, nil, &stacks)
+	return
+}
+
+// KubernetesStackCreateRequest is the payload for KubernetesStackCreate.
+type KubernetesStackCreateRequest struct {
+	StackName        string
+	Namespace        string
+	StackFileContent string
+}
+
+// KubernetesStackCreate creates a Kubernetes stack from a manifest on the
+// given endpoint, mirroring Portainer's Kubernetes stack creation endpoint.
+func (c *Client) KubernetesStackCreate(endpointID portainer.EndpointID, request KubernetesStackCreateRequest) (stack portainer.Stack, err error) {
+	err = c.DoJSONWithToken(fmt.Sprintf("stacks/create/kubernetes/string?endpointId=%d", endpointID), http.MethodPost, http.Header{}, request, &stack)
+	return
+}
+
+// KubernetesStackUpdateRequest is the payload for KubernetesStackUpdate.
+type KubernetesStackUpdateRequest struct {
+	StackFileContent string
+}
+
+// KubernetesStackUpdate updates an existing Kubernetes stack's manifest.
+func (c *Client) KubernetesStackUpdate(stackID portainer.StackID, endpointID portainer.EndpointID, request KubernetesStackUpdateRequest) (err error) {
+	err = c.DoJSONWithToken(fmt.Sprintf("stacks/%d?endpointId=%d", stackID, endpointID), http.MethodPut, http.Header{}, request, nil)
+	return
+}
+
+// StackCreateRequest is the payload for StackCreate. SwarmID is only used
+// for StackTypeSwarm; it's ignored when creating a StackTypeCompose stack.
+type StackCreateRequest struct {
+	Name             string
+	SwarmID          string
+	StackFileContent string
+}
+
+// StackCreate creates a Swarm or Compose stack from a manifest on the given
+// endpoint, mirroring Portainer's Swarm/Compose stack creation endpoint.
+// Use KubernetesStackCreate for StackTypeKubernetes.
+func (c *Client) StackCreate(endpointID portainer.EndpointID, stackType StackType, request StackCreateRequest) (stack portainer.Stack, err error) {
+	err = c.DoJSONWithToken(fmt.Sprintf("stacks?type=%d&method=string&endpointId=%d", stackType, endpointID), http.MethodPost, http.Header{}, request, &stack)
+	return
+}
+
+// StackUpdateRequest is the payload for StackUpdate.
+type StackUpdateRequest struct {
+	StackFileContent string
+}
+
+// StackUpdate updates an existing Swarm or Compose stack's manifest. Use
+// KubernetesStackUpdate for StackTypeKubernetes.
+func (c *Client) StackUpdate(stackID portainer.StackID, endpointID portainer.EndpointID, request StackUpdateRequest) (err error) {
+	err = c.DoJSONWithToken(fmt.Sprintf("stacks/%d?endpointId=%d", stackID, endpointID), http.MethodPut, http.Header{}, request, nil)
+	return
+}
+
+// StackStart starts a previously stopped stack without redeploying it.
+func (c *Client) StackStart(stackID portainer.StackID, endpointID portainer.EndpointID) (err error) {
+	err = c.DoJSONWithToken(fmt.Sprintf("stacks/%d/start?endpointId=%d", stackID, endpointID), http.MethodPost, http.Header{}, nil, nil)
+	return
+}
+
+// StackStop stops a stack without deleting it, so it can be started again later.
+func (c *Client) StackStop(stackID portainer.StackID, endpointID portainer.EndpointID) (err error) {
+	err = c.DoJSONWithToken(fmt.Sprintf("stacks/%d/stop?endpointId=%d", stackID, endpointID), http.MethodPost, http.Header{}, nil, nil)
+	return
+}
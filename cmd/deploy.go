@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/greenled/portainer-stack-utils/client"
+	"github.com/greenled/portainer-stack-utils/common"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deployFile      string
+	deployNamespace string
+)
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy NAME",
+	Short: "Deploy or update a stack from a manifest",
+	Long: `Deploy or update a stack from a manifest, dispatching to the Swarm,
+Compose or Kubernetes stack API depending on the endpoint's detected
+orchestrator. If a stack named NAME already exists (within the endpoint's
+swarm, or namespace for Kubernetes), it's updated in place; otherwise it's
+created.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := runDeploy(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(common.ExitCode(err))
+	},
+}
+
+func runDeploy(stackName string) error {
+	manifest, err := ioutil.ReadFile(deployFile)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := common.GetDefaultEndpoint()
+	if err != nil {
+		return err
+	}
+
+	orchestrator, err := common.GetEndpointOrchestrator(endpoint.ID)
+	if err != nil {
+		return err
+	}
+
+	scopeID := deployNamespace
+	if orchestrator != client.StackTypeKubernetes {
+		scopeID, err = common.GetEndpointSwarmClusterID(endpoint.ID)
+		if err != nil && !common.IsNotFound(err) {
+			return err
+		}
+	}
+
+	portainerClient, err := common.GetClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Deploying stack %q to endpoint %q as a %s stack\n", stackName, endpoint.Name, stackTypeName(orchestrator))
+
+	stack, err := common.GetStackByName(stackName, scopeID, endpoint.ID)
+	switch {
+	case err == nil:
+		return updateStack(portainerClient, orchestrator, stack.ID, endpoint.ID, manifest)
+	case common.IsNotFound(err):
+		return createStack(portainerClient, orchestrator, stackName, scopeID, endpoint.ID, manifest)
+	default:
+		return err
+	}
+}
+
+func createStack(portainerClient *client.Client, orchestrator client.StackType, stackName string, scopeID string, endpointID portainer.EndpointID, manifest []byte) (err error) {
+	if orchestrator == client.StackTypeKubernetes {
+		_, err = portainerClient.KubernetesStackCreate(endpointID, client.KubernetesStackCreateRequest{
+			StackName:        stackName,
+			Namespace:        scopeID,
+			StackFileContent: string(manifest),
+		})
+		return
+	}
+
+	_, err = portainerClient.StackCreate(endpointID, orchestrator, client.StackCreateRequest{
+		Name:             stackName,
+		SwarmID:          scopeID,
+		StackFileContent: string(manifest),
+	})
+	return
+}
+
+func updateStack(portainerClient *client.Client, orchestrator client.StackType, stackID portainer.StackID, endpointID portainer.EndpointID, manifest []byte) error {
+	if orchestrator == client.StackTypeKubernetes {
+		return portainerClient.KubernetesStackUpdate(stackID, endpointID, client.KubernetesStackUpdateRequest{
+			StackFileContent: string(manifest),
+		})
+	}
+
+	return portainerClient.StackUpdate(stackID, endpointID, client.StackUpdateRequest{
+		StackFileContent: string(manifest),
+	})
+}
+
+// stackTypeName returns the human-readable name of a stack type, for
+// logging.
+func stackTypeName(stackType client.StackType) string {
+	switch stackType {
+	case client.StackTypeSwarm:
+		return "Swarm"
+	case client.StackTypeCompose:
+		return "Compose"
+	case client.StackTypeKubernetes:
+		return "Kubernetes"
+	default:
+		return "unknown"
+	}
+}
+
+func init() {
+	deployCmd.Flags().StringVarP(&deployFile, "file", "f", "docker-compose.yml", "Path to the stack manifest to deploy")
+	deployCmd.Flags().StringVar(&deployNamespace, "namespace", "default", "Kubernetes namespace to deploy to (ignored for Swarm/Compose endpoints)")
+	rootCmd.AddCommand(deployCmd)
+}
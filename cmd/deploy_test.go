@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/greenled/portainer-stack-utils/client"
+)
+
+func TestStackTypeName(t *testing.T) {
+	tests := []struct {
+		name      string
+		stackType client.StackType
+		want      string
+	}{
+		{"swarm", client.StackTypeSwarm, "Swarm"},
+		{"compose", client.StackTypeCompose, "Compose"},
+		{"kubernetes", client.StackTypeKubernetes, "Kubernetes"},
+		{"unknown", client.StackType(0), "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stackTypeName(tt.stackType); got != tt.want {
+				t.Errorf("stackTypeName(%v) = %q, want %q", tt.stackType, got, tt.want)
+			}
+		})
+	}
+}
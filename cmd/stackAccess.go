@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/greenled/portainer-stack-utils/common"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	stackAccessOutput             string
+	stackAccessFormat             string
+	stackAccessJSONPath           string
+	stackAccessAdministratorsOnly bool
+	stackAccessPublic             bool
+	stackAccessUserNames          []string
+	stackAccessTeamNames          []string
+)
+
+var stackAccessCmd = &cobra.Command{
+	Use:   "access",
+	Short: "Manage a stack's access control",
+}
+
+var stackAccessGetCmd = &cobra.Command{
+	Use:   "get NAME",
+	Short: "Get a stack's access control",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := runStackAccessGet(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(common.ExitCode(err))
+	},
+}
+
+func runStackAccessGet(stackName string) error {
+	endpoint, err := common.GetDefaultEndpoint()
+	if err != nil {
+		return err
+	}
+
+	resourceControl, err := common.GetStackPortainerAccessControl(endpoint.ID, stackName)
+	if err != nil {
+		return err
+	}
+
+	return common.Print(os.Stdout, resourceControl, common.PrintOptions{
+		Format:   common.OutputFormat(stackAccessOutput),
+		Template: stackAccessFormat,
+		JSONPath: stackAccessJSONPath,
+	})
+}
+
+var stackAccessSetCmd = &cobra.Command{
+	Use:   "set NAME",
+	Short: "Set a stack's access control",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := runStackAccessSet(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(common.ExitCode(err))
+	},
+}
+
+func runStackAccessSet(stackName string) error {
+	endpoint, err := common.GetDefaultEndpoint()
+	if err != nil {
+		return err
+	}
+
+	spec, err := common.ResolveAccessControlSpec(stackAccessAdministratorsOnly, stackAccessPublic, stackAccessUserNames, stackAccessTeamNames)
+	if err != nil {
+		return err
+	}
+
+	_, err = common.GetStackPortainerAccessControl(endpoint.ID, stackName)
+	if err != nil && !common.IsNotFound(err) {
+		return err
+	} else if err == nil {
+		_, err = common.UpdateStackAccessControl(endpoint.ID, stackName, spec)
+		return err
+	}
+
+	_, err = common.SetStackAccessControl(endpoint.ID, stackName, spec)
+	return err
+}
+
+var stackAccessRmCmd = &cobra.Command{
+	Use:     "rm NAME",
+	Aliases: []string{"remove"},
+	Short:   "Remove a stack's access control",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		endpoint, err := common.GetDefaultEndpoint()
+		if err == nil {
+			err = common.DeleteStackAccessControl(endpoint.ID, args[0])
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(common.ExitCode(err))
+	},
+}
+
+func init() {
+	stackAccessGetCmd.Flags().StringVarP(&stackAccessOutput, "output", "o", string(common.OutputFormatTemplate), "Output format: json, yaml or template")
+	stackAccessGetCmd.Flags().StringVarP(&stackAccessFormat, "format", "f", "{{.}}\n", "Go template to render the access control with")
+	stackAccessGetCmd.Flags().StringVar(&stackAccessJSONPath, "jsonpath", "", "JSONPath expression to render the access control with (only used with -o/--output template)")
+
+	stackAccessSetCmd.Flags().BoolVar(&stackAccessAdministratorsOnly, "administrators-only", false, "Restrict access to administrators")
+	stackAccessSetCmd.Flags().BoolVar(&stackAccessPublic, "public", false, "Make the stack accessible to everyone")
+	stackAccessSetCmd.Flags().StringSliceVar(&stackAccessUserNames, "user", nil, "Grant access to a user (can be repeated)")
+	stackAccessSetCmd.Flags().StringSliceVar(&stackAccessTeamNames, "team", nil, "Grant access to a team (can be repeated)")
+
+	stackAccessCmd.AddCommand(stackAccessGetCmd, stackAccessSetCmd, stackAccessRmCmd)
+	stackCmd.AddCommand(stackAccessCmd)
+}
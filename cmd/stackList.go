@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/greenled/portainer-stack-utils/client"
+	"github.com/greenled/portainer-stack-utils/common"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stackListFilterName string
+	stackListFilterEnv  map[string]string
+	stackListFilterType string
+	stackListOrphaned   bool
+	stackListOutput     string
+	stackListFormat     string
+	stackListJSONPath   string
+)
+
+var stackListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List stacks",
+	Long: `List stacks, optionally narrowed down by name, environment variable,
+type or orphaned status.
+` + common.GetFormatHelp([]portainer.Stack{}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackType, err := parseStackType(stackListFilterType)
+		if err != nil {
+			return err
+		}
+
+		// Name, Env and Type aren't honored by Portainer's stack list
+		// filters server-side, so they're applied here once the list
+		// comes back. The list is not scoped to an endpoint or swarm.
+		stacks, err := common.ListStacks(client.StackListFilter{
+			IncludeOrphanedStacks: stackListOrphaned,
+		})
+		if err != nil {
+			return err
+		}
+
+		stacks = filterStacksByName(stacks, stackListFilterName)
+		stacks = filterStacksByEnv(stacks, stackListFilterEnv)
+		stacks = filterStacksByType(stacks, stackType)
+
+		return common.Print(os.Stdout, stacks, common.PrintOptions{
+			Format:   common.OutputFormat(stackListOutput),
+			Template: stackListFormat,
+			JSONPath: stackListJSONPath,
+		})
+	},
+}
+
+// filterStacksByName keeps only stacks with the given name. An empty name
+// leaves the list untouched.
+func filterStacksByName(stacks []portainer.Stack, name string) []portainer.Stack {
+	if name == "" {
+		return stacks
+	}
+
+	filtered := make([]portainer.Stack, 0, len(stacks))
+	for _, stack := range stacks {
+		if stack.Name == name {
+			filtered = append(filtered, stack)
+		}
+	}
+	return filtered
+}
+
+// filterStacksByEnv keeps only stacks whose environment variables contain
+// every key/value pair in env. An empty env map leaves the list untouched.
+//
+// Portainer stacks don't carry generic key/value labels outside of their
+// environment variables, so this (and --filter) only ever matches env vars,
+// not resource-control labels.
+func filterStacksByEnv(stacks []portainer.Stack, env map[string]string) []portainer.Stack {
+	if len(env) == 0 {
+		return stacks
+	}
+
+	filtered := make([]portainer.Stack, 0, len(stacks))
+	for _, stack := range stacks {
+		if stackMatchesEnv(stack, env) {
+			filtered = append(filtered, stack)
+		}
+	}
+	return filtered
+}
+
+func stackMatchesEnv(stack portainer.Stack, env map[string]string) bool {
+	for key, value := range env {
+		if !stackHasEnvVar(stack, key, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func stackHasEnvVar(stack portainer.Stack, name string, value string) bool {
+	for _, envVar := range stack.Env {
+		if envVar.Name == name && envVar.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// filterStacksByType keeps only stacks of the given type. A zero stackType
+// leaves the list untouched.
+func filterStacksByType(stacks []portainer.Stack, stackType portainer.StackType) []portainer.Stack {
+	if stackType == 0 {
+		return stacks
+	}
+
+	filtered := make([]portainer.Stack, 0, len(stacks))
+	for _, stack := range stacks {
+		if stack.Type == stackType {
+			filtered = append(filtered, stack)
+		}
+	}
+	return filtered
+}
+
+// parseStackType parses --type into the portainer.StackType it names, or
+// the zero value for "" (no filtering).
+func parseStackType(s string) (portainer.StackType, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return 0, nil
+	case "swarm":
+		return portainer.StackType(client.StackTypeSwarm), nil
+	case "compose":
+		return portainer.StackType(client.StackTypeCompose), nil
+	case "kubernetes":
+		return portainer.StackType(client.StackTypeKubernetes), nil
+	default:
+		return 0, fmt.Errorf("unknown stack type %q, want one of: swarm, compose, kubernetes", s)
+	}
+}
+
+func init() {
+	stackListCmd.Flags().StringVar(&stackListFilterName, "name", "", "Filter stacks by name")
+	stackListCmd.Flags().StringToStringVar(&stackListFilterEnv, "filter", map[string]string{}, "Filter stacks by environment variable, e.g. --filter key=value")
+	stackListCmd.Flags().StringVar(&stackListFilterType, "type", "", "Filter stacks by type: swarm, compose or kubernetes")
+	stackListCmd.Flags().BoolVar(&stackListOrphaned, "orphaned", false, "Include stacks whose endpoint no longer exists")
+	stackListCmd.Flags().StringVarP(&stackListOutput, "output", "o", string(common.OutputFormatTemplate), "Output format: json, yaml or template")
+	stackListCmd.Flags().StringVarP(&stackListFormat, "format", "f", "{{range .}}{{.Name}}\n{{end}}", "Go template to render the stack list with")
+	stackListCmd.Flags().StringVar(&stackListJSONPath, "jsonpath", "", "JSONPath expression to render the stack list with, e.g. '{[*].Name}' (only used with -o/--output template)")
+	stackCmd.AddCommand(stackListCmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/greenled/portainer-stack-utils/common"
+
+	"github.com/spf13/cobra"
+)
+
+var stackStartCmd = &cobra.Command{
+	Use:   "start NAME",
+	Short: "Start a stopped stack",
+	Long:  `Start a stack that was previously stopped with "stack stop", without redeploying it.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := runStackStart(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(common.ExitCode(err))
+	},
+}
+
+func runStackStart(stackName string) error {
+	endpoint, err := common.GetDefaultEndpoint()
+	if err != nil {
+		return err
+	}
+
+	endpointSwarmClusterID, err := common.GetEndpointSwarmClusterID(endpoint.ID)
+	if err != nil && !common.IsNotFound(err) {
+		return err
+	}
+
+	stack, err := common.GetStackByName(stackName, endpointSwarmClusterID, endpoint.ID)
+	if err != nil {
+		return err
+	}
+
+	portainerClient, err := common.GetClient()
+	if err != nil {
+		return err
+	}
+
+	return portainerClient.StackStart(stack.ID, endpoint.ID)
+}
+
+func init() {
+	stackCmd.AddCommand(stackStartCmd)
+}
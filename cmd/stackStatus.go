@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/greenled/portainer-stack-utils/client"
+	"github.com/greenled/portainer-stack-utils/common"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stackStatusOutput   string
+	stackStatusFormat   string
+	stackStatusJSONPath string
+)
+
+var stackStatusCmd = &cobra.Command{
+	Use:   "status NAME",
+	Short: "Report whether a stack is active or inactive",
+	Long:  `Report whether a stack is active or inactive, so scripts can gate "stack start"/"stack stop" on its current state.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		status, err := runStackStatus(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(common.ExitCode(err))
+		}
+
+		err = common.Print(os.Stdout, stackStatusName(status), common.PrintOptions{
+			Format:   common.OutputFormat(stackStatusOutput),
+			Template: stackStatusFormat,
+			JSONPath: stackStatusJSONPath,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(common.ExitCode(err))
+	},
+}
+
+func runStackStatus(stackName string) (status portainer.StackStatus, err error) {
+	endpoint, err := common.GetDefaultEndpoint()
+	if err != nil {
+		return
+	}
+
+	orchestrator, err := common.GetEndpointOrchestrator(endpoint.ID)
+	if err != nil {
+		return
+	}
+
+	var scopeID string
+	if orchestrator != client.StackTypeKubernetes {
+		scopeID, err = common.GetEndpointSwarmClusterID(endpoint.ID)
+		if err != nil && !common.IsNotFound(err) {
+			return
+		}
+	}
+
+	status, err = common.GetStackStatus(stackName, scopeID, endpoint.ID)
+	return
+}
+
+// stackStatusName returns the human-readable name of a stack status, for
+// display.
+func stackStatusName(status portainer.StackStatus) string {
+	switch status {
+	case portainer.StackStatusActive:
+		return "active"
+	case portainer.StackStatusInactive:
+		return "inactive"
+	default:
+		return "unknown"
+	}
+}
+
+func init() {
+	stackStatusCmd.Flags().StringVarP(&stackStatusOutput, "output", "o", string(common.OutputFormatTemplate), "Output format: json, yaml or template")
+	stackStatusCmd.Flags().StringVarP(&stackStatusFormat, "format", "f", "{{.}}\n", "Go template to render the status with")
+	stackStatusCmd.Flags().StringVar(&stackStatusJSONPath, "jsonpath", "", "JSONPath expression to render the status with (only used with -o/--output template)")
+	stackCmd.AddCommand(stackStatusCmd)
+}
@@ -0,0 +1,146 @@
+package common
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/greenled/portainer-stack-utils/client"
+
+	"github.com/spf13/cobra"
+)
+
+// AccessCmdInitFunc wires "access get/set/rm" subcommands for a Docker
+// resource type (container, service, volume, network, config or secret)
+// under resourceCmd.
+func AccessCmdInitFunc(resourceCmd *cobra.Command, resourceType client.ResourceType) {
+	var (
+		output             string
+		format             string
+		jsonPath           string
+		administratorsOnly bool
+		public             bool
+		userNames          []string
+		teamNames          []string
+	)
+
+	accessCmd := &cobra.Command{
+		Use:   "access",
+		Short: "Manage a " + string(resourceType) + "'s access control",
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get ID",
+		Short: "Get a " + string(resourceType) + "'s access control",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := func() error {
+				endpoint, err := GetDefaultEndpoint()
+				if err != nil {
+					return err
+				}
+
+				resourceControl, err := GetDockerResourcePortainerAccessControl(endpoint.ID, args[0], resourceType)
+				if err != nil {
+					return err
+				}
+
+				return Print(os.Stdout, resourceControl, PrintOptions{
+					Format:   OutputFormat(output),
+					Template: format,
+					JSONPath: jsonPath,
+				})
+			}()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(ExitCode(err))
+		},
+	}
+	getCmd.Flags().StringVarP(&output, "output", "o", string(OutputFormatTemplate), "Output format: json, yaml or template")
+	getCmd.Flags().StringVarP(&format, "format", "f", "{{.}}\n", "Go template to render the access control with")
+	getCmd.Flags().StringVar(&jsonPath, "jsonpath", "", "JSONPath expression to render the access control with (only used with -o/--output template)")
+
+	setCmd := &cobra.Command{
+		Use:   "set ID",
+		Short: "Set a " + string(resourceType) + "'s access control",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := func() error {
+				endpoint, err := GetDefaultEndpoint()
+				if err != nil {
+					return err
+				}
+
+				spec, err := ResolveAccessControlSpec(administratorsOnly, public, userNames, teamNames)
+				if err != nil {
+					return err
+				}
+
+				_, err = GetDockerResourcePortainerAccessControl(endpoint.ID, args[0], resourceType)
+				if err != nil && !IsNotFound(err) {
+					return err
+				} else if err == nil {
+					_, err = UpdateDockerResourceAccessControl(endpoint.ID, args[0], resourceType, spec)
+					return err
+				}
+
+				_, err = SetDockerResourceAccessControl(args[0], resourceType, spec)
+				return err
+			}()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(ExitCode(err))
+		},
+	}
+	setCmd.Flags().BoolVar(&administratorsOnly, "administrators-only", false, "Restrict access to administrators")
+	setCmd.Flags().BoolVar(&public, "public", false, "Make the resource accessible to everyone")
+	setCmd.Flags().StringSliceVar(&userNames, "user", nil, "Grant access to a user (can be repeated)")
+	setCmd.Flags().StringSliceVar(&teamNames, "team", nil, "Grant access to a team (can be repeated)")
+
+	rmCmd := &cobra.Command{
+		Use:     "rm ID",
+		Aliases: []string{"remove"},
+		Short:   "Remove a " + string(resourceType) + "'s access control",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			endpoint, err := GetDefaultEndpoint()
+			if err == nil {
+				err = DeleteDockerResourceAccessControl(endpoint.ID, args[0], resourceType)
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(ExitCode(err))
+		},
+	}
+
+	accessCmd.AddCommand(getCmd, setCmd, rmCmd)
+	resourceCmd.AddCommand(accessCmd)
+}
+
+// ResolveAccessControlSpec resolves --user/--team names into their
+// Portainer IDs before building the access control spec sent to the
+// server.
+func ResolveAccessControlSpec(administratorsOnly bool, public bool, userNames []string, teamNames []string) (spec client.AccessControlSpec, err error) {
+	spec.AdministratorsOnly = administratorsOnly
+	spec.Public = public
+
+	for _, userName := range userNames {
+		user, err := GetUserByName(userName)
+		if err != nil {
+			return spec, err
+		}
+		spec.Users = append(spec.Users, user.ID)
+	}
+
+	for _, teamName := range teamNames {
+		team, err := GetTeamByName(teamName)
+		if err != nil {
+			return spec, err
+		}
+		spec.Teams = append(spec.Teams, team.ID)
+	}
+
+	return spec, nil
+}
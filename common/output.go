@@ -0,0 +1,65 @@
+package common
+
+import (
+	"encoding/json"
+	"io"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// OutputFormat selects how Print renders a value.
+type OutputFormat string
+
+// Supported output formats for the --output flag.
+const (
+	OutputFormatTemplate OutputFormat = "template"
+	OutputFormatJSON     OutputFormat = "json"
+	OutputFormatYAML     OutputFormat = "yaml"
+)
+
+// PrintOptions configures Print.
+type PrintOptions struct {
+	// Format selects the renderer. Defaults to OutputFormatTemplate.
+	Format OutputFormat
+	// Template is a Go template body, used when Format is
+	// OutputFormatTemplate and JSONPath is empty.
+	Template string
+	// JSONPath is a k8s.io/client-go/util/jsonpath expression, used when
+	// Format is OutputFormatTemplate and non-empty. It takes precedence
+	// over Template.
+	JSONPath string
+}
+
+// Print renders v to w as JSON, YAML or a Go template/JSONPath expression,
+// according to options. This makes command output composable with jq/yq
+// pipelines in addition to the original template-only --format flag.
+func Print(w io.Writer, v interface{}, options PrintOptions) error {
+	switch options.Format {
+	case OutputFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case OutputFormatYAML:
+		encoded, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(encoded)
+		return err
+	default:
+		if options.JSONPath != "" {
+			jp := jsonpath.New("format")
+			if err := jp.Parse(options.JSONPath); err != nil {
+				return err
+			}
+			return jp.Execute(w, v)
+		}
+		tmpl, err := template.New("format").Parse(options.Template)
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, v)
+	}
+}
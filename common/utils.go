@@ -7,22 +7,11 @@ import (
 
 	"github.com/greenled/portainer-stack-utils/client"
 
+	"github.com/pkg/errors"
 	portainer "github.com/portainer/portainer/api"
 	"github.com/sirupsen/logrus"
 )
 
-// Common errors
-const (
-	ErrStackNotFound             = Error("Stack not found")
-	ErrStackClusterNotFound      = Error("Stack cluster not found")
-	ErrEndpointNotFound          = Error("Endpoint not found")
-	ErrEndpointGroupNotFound     = Error("Endpoint group not found")
-	ErrSeveralEndpointsAvailable = Error("Several endpoints available")
-	ErrNoEndpointsAvailable      = Error("No endpoints available")
-	ErrUserNotFound              = Error("User not found")
-	ErrAccessControlNotFound     = Error("Access control not found")
-)
-
 const (
 	valueNotFoundError = Error("Value not found")
 )
@@ -35,6 +24,172 @@ func (e Error) Error() string {
 	return string(e)
 }
 
+// Common errors, categorized by the typed interfaces below so callers (in
+// particular cmd/*) can branch on the failure kind instead of matching
+// error strings.
+var (
+	errStackNotFound             = AsNotFound(Error("stack not found"))
+	errStackClusterNotFound      = AsNotFound(Error("stack cluster not found"))
+	errEndpointNotFound          = AsNotFound(Error("endpoint not found"))
+	errEndpointGroupNotFound     = AsNotFound(Error("endpoint group not found"))
+	errSeveralEndpointsAvailable = AsAmbiguous(Error("several endpoints available"))
+	errNoEndpointsAvailable      = AsNotFound(Error("no endpoints available"))
+	errUserNotFound              = AsNotFound(Error("user not found"))
+	errTeamNotFound              = AsNotFound(Error("team not found"))
+	errAccessControlNotFound     = AsNotFound(Error("access control not found"))
+)
+
+// NotFound is implemented by errors reporting that a requested resource
+// does not exist. This (and the other marker interfaces below) mirrors the
+// errdefs pattern used by Docker/Moby: wrap a root cause with AsNotFound,
+// AsConflict, etc., then let callers test for the category with IsNotFound,
+// IsConflict, etc. regardless of how much context has been layered on top
+// with errors.Wrap.
+type NotFound interface {
+	error
+	NotFound()
+}
+
+// Conflict is implemented by errors reporting that a request could not be
+// completed due to a conflict with the current state of a resource.
+type Conflict interface {
+	error
+	Conflict()
+}
+
+// Unauthorized is implemented by errors reporting that a request was
+// rejected for lack of valid credentials.
+type Unauthorized interface {
+	error
+	Unauthorized()
+}
+
+// Forbidden is implemented by errors reporting that a request was rejected
+// because the caller is not allowed to perform it.
+type Forbidden interface {
+	error
+	Forbidden()
+}
+
+// Ambiguous is implemented by errors reporting that a request matched more
+// than one resource when exactly one was expected.
+type Ambiguous interface {
+	error
+	Ambiguous()
+}
+
+type notFoundError struct{ error }
+
+func (notFoundError) NotFound() {}
+
+type conflictError struct{ error }
+
+func (conflictError) Conflict() {}
+
+type unauthorizedError struct{ error }
+
+func (unauthorizedError) Unauthorized() {}
+
+type forbiddenError struct{ error }
+
+func (forbiddenError) Forbidden() {}
+
+type ambiguousError struct{ error }
+
+func (ambiguousError) Ambiguous() {}
+
+// AsNotFound wraps err so it satisfies NotFound.
+func AsNotFound(err error) error { return notFoundError{err} }
+
+// AsConflict wraps err so it satisfies Conflict.
+func AsConflict(err error) error { return conflictError{err} }
+
+// AsUnauthorized wraps err so it satisfies Unauthorized.
+func AsUnauthorized(err error) error { return unauthorizedError{err} }
+
+// AsForbidden wraps err so it satisfies Forbidden.
+func AsForbidden(err error) error { return forbiddenError{err} }
+
+// AsAmbiguous wraps err so it satisfies Ambiguous.
+func AsAmbiguous(err error) error { return ambiguousError{err} }
+
+// IsNotFound reports whether err, or any error it wraps via errors.Wrap,
+// satisfies NotFound.
+func IsNotFound(err error) bool {
+	return hasCategory(err, func(err error) bool { _, ok := err.(NotFound); return ok })
+}
+
+// IsConflict reports whether err, or any error it wraps via errors.Wrap,
+// satisfies Conflict.
+func IsConflict(err error) bool {
+	return hasCategory(err, func(err error) bool { _, ok := err.(Conflict); return ok })
+}
+
+// IsUnauthorized reports whether err, or any error it wraps via
+// errors.Wrap, satisfies Unauthorized.
+func IsUnauthorized(err error) bool {
+	return hasCategory(err, func(err error) bool { _, ok := err.(Unauthorized); return ok })
+}
+
+// IsForbidden reports whether err, or any error it wraps via errors.Wrap,
+// satisfies Forbidden.
+func IsForbidden(err error) bool {
+	return hasCategory(err, func(err error) bool { _, ok := err.(Forbidden); return ok })
+}
+
+// IsAmbiguous reports whether err, or any error it wraps via errors.Wrap,
+// satisfies Ambiguous.
+func IsAmbiguous(err error) bool {
+	return hasCategory(err, func(err error) bool { _, ok := err.(Ambiguous); return ok })
+}
+
+// Exit codes returned by ExitCode, picked to distinguish the error
+// categories a CI pipeline might want to branch on.
+const (
+	ExitCodeOK = iota
+	ExitCodeError
+	ExitCodeNotFound
+	ExitCodeConflict
+	ExitCodeUnauthorized
+	ExitCodeForbidden
+)
+
+// ExitCode maps err to a process exit code, so cmd/* can report not found,
+// conflict, unauthorized and forbidden failures distinctly from each other
+// and from a generic error.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitCodeOK
+	case IsNotFound(err):
+		return ExitCodeNotFound
+	case IsConflict(err):
+		return ExitCodeConflict
+	case IsUnauthorized(err):
+		return ExitCodeUnauthorized
+	case IsForbidden(err):
+		return ExitCodeForbidden
+	default:
+		return ExitCodeError
+	}
+}
+
+// hasCategory walks the chain of causes behind err (as built by
+// errors.Wrap) looking for one that matches is.
+func hasCategory(err error, is func(error) bool) bool {
+	for err != nil {
+		if is(err) {
+			return true
+		}
+		causer, ok := err.(interface{ Cause() error })
+		if !ok {
+			return false
+		}
+		err = causer.Cause()
+	}
+	return false
+}
+
 // GetDefaultEndpoint returns the default endpoint (if only one endpoint exists)
 func GetDefaultEndpoint() (endpoint portainer.Endpoint, err error) {
 	portainerClient, err := GetClient()
@@ -49,10 +204,10 @@ func GetDefaultEndpoint() (endpoint portainer.Endpoint, err error) {
 	}
 
 	if len(endpoints) == 0 {
-		err = ErrNoEndpointsAvailable
+		err = errors.Wrap(errNoEndpointsAvailable, "getting default endpoint")
 		return
 	} else if len(endpoints) > 1 {
-		err = ErrSeveralEndpointsAvailable
+		err = errors.Wrap(errSeveralEndpointsAvailable, "getting default endpoint")
 		return
 	}
 	endpoint = endpoints[0]
@@ -60,30 +215,65 @@ func GetDefaultEndpoint() (endpoint portainer.Endpoint, err error) {
 	return
 }
 
-// GetStackByName returns a stack by its name from the (endpoint filtered) list
-// of all stacks
-func GetStackByName(name string, swarmID string, endpointID portainer.EndpointID) (stack portainer.Stack, err error) {
+// ListStacks returns the stacks matching filter. Filtering is done
+// server-side by Portainer; this does not re-fetch and loop over every
+// stack in Go.
+func ListStacks(filter client.StackListFilter) (stacks []portainer.Stack, err error) {
 	portainerClient, err := GetClient()
 	if err != nil {
 		return
 	}
 
-	stacks, err := portainerClient.StackList(client.StackListOptions{
-		Filter: client.StackListFilter{
-			SwarmID:    swarmID,
-			EndpointID: endpointID,
-		},
-	})
+	stacks, err = portainerClient.StackList(client.StackListOptions{Filter: filter})
+	if err != nil {
+		err = errors.Wrap(err, "listing stacks")
+	}
+	return
+}
+
+// GetStackByName returns a stack by its name from the (endpoint filtered)
+// list of all stacks. scopeID is either a Swarm cluster ID or, for
+// Kubernetes-backed stacks, a namespace; which of the two it is matched
+// against is resolved from the endpoint's detected orchestrator.
+//
+// Portainer's own stack list filters only narrow down by SwarmID,
+// Namespace, EndpointID and IncludeOrphanedStacks server-side, so the name is
+// re-checked here rather than trusting the first result back.
+func GetStackByName(name string, scopeID string, endpointID portainer.EndpointID) (stack portainer.Stack, err error) {
+	filter := client.StackListFilter{EndpointID: endpointID}
+
+	orchestrator, err := GetEndpointOrchestrator(endpointID)
+	if err != nil {
+		return
+	}
+	if orchestrator == client.StackTypeKubernetes {
+		filter.Namespace = scopeID
+	} else {
+		filter.SwarmID = scopeID
+	}
+
+	stacks, err := ListStacks(filter)
 	if err != nil {
 		return
 	}
 
-	for _, stack := range stacks {
-		if stack.Name == name {
-			return stack, nil
+	for _, candidate := range stacks {
+		if candidate.Name == name {
+			return candidate, nil
 		}
 	}
-	err = ErrStackNotFound
+	err = errors.Wrapf(errStackNotFound, "getting stack %q", name)
+	return
+}
+
+// GetStackStatus returns whether a stack is currently active or inactive, so
+// scripts can gate "stack start"/"stack stop" on the stack's current state.
+func GetStackStatus(name string, scopeID string, endpointID portainer.EndpointID) (status portainer.StackStatus, err error) {
+	stack, err := GetStackByName(name, scopeID, endpointID)
+	if err != nil {
+		return
+	}
+	status = stack.Status
 	return
 }
 
@@ -105,7 +295,7 @@ func GetEndpointByName(name string) (endpoint portainer.Endpoint, err error) {
 			return endpoint, nil
 		}
 	}
-	err = ErrEndpointNotFound
+	err = errors.Wrapf(errEndpointNotFound, "getting endpoint %q", name)
 	return
 }
 
@@ -127,7 +317,7 @@ func GetEndpointGroupByName(name string) (endpointGroup portainer.EndpointGroup,
 			return endpointGroup, nil
 		}
 	}
-	err = ErrEndpointGroupNotFound
+	err = errors.Wrapf(errEndpointGroupNotFound, "getting endpoint group %q", name)
 	return
 }
 
@@ -139,7 +329,7 @@ func GetEndpointFromListByID(endpoints []portainer.Endpoint, id portainer.Endpoi
 			return endpoints[i], err
 		}
 	}
-	return endpoint, ErrEndpointNotFound
+	return endpoint, errors.Wrapf(errEndpointNotFound, "getting endpoint %d", id)
 }
 
 // GetEndpointFromListByName returns an endpoint by its name from a list of
@@ -150,7 +340,7 @@ func GetEndpointFromListByName(endpoints []portainer.Endpoint, name string) (end
 			return endpoints[i], err
 		}
 	}
-	return endpoint, ErrEndpointNotFound
+	return endpoint, errors.Wrapf(errEndpointNotFound, "getting endpoint %q", name)
 }
 
 // GetEndpointSwarmClusterID returns an endpoint's swarm cluster id
@@ -171,7 +361,7 @@ func GetEndpointSwarmClusterID(endpointID portainer.EndpointID) (endpointSwarmCl
 	if selectionErr == nil {
 		endpointSwarmClusterID = id.(string)
 	} else if selectionErr == valueNotFoundError {
-		err = ErrStackClusterNotFound
+		err = errors.Wrapf(errStackClusterNotFound, "getting swarm cluster id for endpoint %d", endpointID)
 	} else {
 		err = selectionErr
 	}
@@ -179,6 +369,49 @@ func GetEndpointSwarmClusterID(endpointID portainer.EndpointID) (endpointSwarmCl
 	return
 }
 
+// GetEndpointByID returns an endpoint by its id from the list of all
+// endpoints
+func GetEndpointByID(id portainer.EndpointID) (endpoint portainer.Endpoint, err error) {
+	portainerClient, err := GetClient()
+	if err != nil {
+		return
+	}
+
+	endpoints, err := portainerClient.EndpointList()
+	if err != nil {
+		return
+	}
+
+	return GetEndpointFromListByID(endpoints, id)
+}
+
+// GetEndpointOrchestrator detects which orchestrator backs an endpoint, so
+// callers such as the deploy command can dispatch to the matching stack
+// create/update call. Kubernetes endpoints are identified by their
+// EndpointType, since Portainer's docker/info endpoint (unlike the
+// Swarm.Cluster.ID it returns for Swarm endpoints) has no Kubernetes
+// section to probe.
+func GetEndpointOrchestrator(endpointID portainer.EndpointID) (stackType client.StackType, err error) {
+	endpoint, err := GetEndpointByID(endpointID)
+	if err != nil {
+		return
+	}
+
+	switch endpoint.Type {
+	case portainer.KubernetesLocalEnvironment, portainer.AgentOnKubernetesEnvironment, portainer.EdgeAgentOnKubernetesEnvironment:
+		return client.StackTypeKubernetes, nil
+	}
+
+	_, err = GetEndpointSwarmClusterID(endpointID)
+	if err == nil {
+		return client.StackTypeSwarm, nil
+	} else if !IsNotFound(err) {
+		return
+	}
+
+	return client.StackTypeCompose, nil
+}
+
 func selectValue(jsonMap map[string]interface{}, jsonPath []string) (interface{}, error) {
 	value := jsonMap[jsonPath[0]]
 	if value == nil {
@@ -190,7 +423,9 @@ func selectValue(jsonMap map[string]interface{}, jsonPath []string) (interface{}
 	}
 }
 
-// GetFormatHelp returns the help string for --format flags
+// GetFormatHelp returns the help string for --format flags. With --output
+// json or --output yaml the object is marshaled directly instead, and
+// --format/--jsonpath are ignored.
 func GetFormatHelp(v interface{}) (r string) {
 	typeOfV := reflect.TypeOf(v)
 	r = fmt.Sprintf(`
@@ -239,8 +474,31 @@ func GetUserByName(name string) (user portainer.User, err error) {
 	}
 
 	// User not found
-	err = ErrUserNotFound
+	err = errors.Wrapf(errUserNotFound, "getting user %q", name)
+
+	return
+}
+
+// GetTeamByName returns a team by its name from the list of all teams
+func GetTeamByName(name string) (team portainer.Team, err error) {
+	portainerClient, err := GetClient()
+	if err != nil {
+		return
+	}
 
+	teams, err := portainerClient.TeamList()
+	if err != nil {
+		return
+	}
+
+	for _, listTeam := range teams {
+		if listTeam.Name == name {
+			team = listTeam
+			return
+		}
+	}
+
+	err = errors.Wrapf(errTeamNotFound, "getting team %q", name)
 	return
 }
 
@@ -255,13 +513,14 @@ func GetDockerResourcePortainerAccessControl(endpointID portainer.EndpointID, re
 
 	err = portainerClient.DoJSONWithToken(fmt.Sprintf("endpoints/%d/docker/%ss/%s", endpointID, resourceControlType, resourceID), http.MethodGet, http.Header{}, nil, &pddr)
 	if err != nil {
+		err = errors.Wrapf(err, "getting access control for %s %q", resourceControlType, resourceID)
 		return
 	}
 
 	if pddr.hasAccessControl() {
 		resourceControl = pddr.Portainer.ResourceControl
 	} else {
-		err = ErrAccessControlNotFound
+		err = errors.Wrapf(errAccessControlNotFound, "getting access control for %s %q", resourceControlType, resourceID)
 	}
 
 	return
@@ -270,7 +529,7 @@ func GetDockerResourcePortainerAccessControl(endpointID portainer.EndpointID, re
 // GetStackPortainerAccessControl retrieves a stacks's Portainer access control (if any)
 func GetStackPortainerAccessControl(endpointID portainer.EndpointID, stackName string) (resourceControl portainer.ResourceControl, err error) {
 	endpointSwarmClusterID, err := GetEndpointSwarmClusterID(endpointID)
-	if err != nil && err != ErrStackClusterNotFound {
+	if err != nil && !IsNotFound(err) {
 		return
 	}
 
@@ -288,18 +547,136 @@ func GetStackPortainerAccessControl(endpointID portainer.EndpointID, stackName s
 
 	err = portainerClient.DoJSONWithToken(fmt.Sprintf("stacks/%d", stack.ID), http.MethodGet, http.Header{}, nil, &ds)
 	if err != nil {
+		err = errors.Wrapf(err, "getting access control for stack %q", stackName)
 		return
 	}
 
 	if ds.hasAccessControl() {
 		resourceControl = ds.ResourceControl
 	} else {
-		err = ErrAccessControlNotFound
+		err = errors.Wrapf(errAccessControlNotFound, "getting access control for stack %q", stackName)
 	}
 
 	return
 }
 
+// SetStackAccessControl creates a Portainer access control on a stack.
+func SetStackAccessControl(endpointID portainer.EndpointID, stackName string, spec client.AccessControlSpec) (resourceControl portainer.ResourceControl, err error) {
+	endpointSwarmClusterID, err := GetEndpointSwarmClusterID(endpointID)
+	if err != nil && !IsNotFound(err) {
+		return
+	}
+
+	stack, err := GetStackByName(stackName, endpointSwarmClusterID, endpointID)
+	if err != nil {
+		return
+	}
+
+	portainerClient, err := GetClient()
+	if err != nil {
+		return
+	}
+
+	resourceControl, err = portainerClient.ResourceControlCreate(fmt.Sprintf("%d", stack.ID), client.ResourceStack, spec)
+	if err != nil {
+		err = errors.Wrapf(err, "setting access control for stack %q", stackName)
+	}
+	return
+}
+
+// UpdateStackAccessControl updates an existing Portainer access control on a stack.
+func UpdateStackAccessControl(endpointID portainer.EndpointID, stackName string, spec client.AccessControlSpec) (resourceControl portainer.ResourceControl, err error) {
+	existing, err := GetStackPortainerAccessControl(endpointID, stackName)
+	if err != nil {
+		return
+	}
+
+	portainerClient, err := GetClient()
+	if err != nil {
+		return
+	}
+
+	resourceControl, err = portainerClient.ResourceControlUpdate(existing.ID, spec)
+	if err != nil {
+		err = errors.Wrapf(err, "updating access control for stack %q", stackName)
+	}
+	return
+}
+
+// DeleteStackAccessControl removes a stack's Portainer access control.
+func DeleteStackAccessControl(endpointID portainer.EndpointID, stackName string) (err error) {
+	existing, err := GetStackPortainerAccessControl(endpointID, stackName)
+	if err != nil {
+		return
+	}
+
+	portainerClient, err := GetClient()
+	if err != nil {
+		return
+	}
+
+	err = portainerClient.ResourceControlDelete(existing.ID)
+	if err != nil {
+		err = errors.Wrapf(err, "deleting access control for stack %q", stackName)
+	}
+	return
+}
+
+// SetDockerResourceAccessControl creates a Portainer access control on a
+// Docker resource.
+func SetDockerResourceAccessControl(resourceID string, resourceType client.ResourceType, spec client.AccessControlSpec) (resourceControl portainer.ResourceControl, err error) {
+	portainerClient, err := GetClient()
+	if err != nil {
+		return
+	}
+
+	resourceControl, err = portainerClient.ResourceControlCreate(resourceID, resourceType, spec)
+	if err != nil {
+		err = errors.Wrapf(err, "setting access control for %s %q", resourceType, resourceID)
+	}
+	return
+}
+
+// UpdateDockerResourceAccessControl updates an existing Portainer access
+// control on a Docker resource.
+func UpdateDockerResourceAccessControl(endpointID portainer.EndpointID, resourceID string, resourceType client.ResourceType, spec client.AccessControlSpec) (resourceControl portainer.ResourceControl, err error) {
+	existing, err := GetDockerResourcePortainerAccessControl(endpointID, resourceID, resourceType)
+	if err != nil {
+		return
+	}
+
+	portainerClient, err := GetClient()
+	if err != nil {
+		return
+	}
+
+	resourceControl, err = portainerClient.ResourceControlUpdate(existing.ID, spec)
+	if err != nil {
+		err = errors.Wrapf(err, "updating access control for %s %q", resourceType, resourceID)
+	}
+	return
+}
+
+// DeleteDockerResourceAccessControl removes a Docker resource's Portainer
+// access control.
+func DeleteDockerResourceAccessControl(endpointID portainer.EndpointID, resourceID string, resourceType client.ResourceType) (err error) {
+	existing, err := GetDockerResourcePortainerAccessControl(endpointID, resourceID, resourceType)
+	if err != nil {
+		return
+	}
+
+	portainerClient, err := GetClient()
+	if err != nil {
+		return
+	}
+
+	err = portainerClient.ResourceControlDelete(existing.ID)
+	if err != nil {
+		err = errors.Wrapf(err, "deleting access control for %s %q", resourceType, resourceID)
+	}
+	return
+}
+
 // portainerDecoratedDockerResource represents a Docker resource decorated by Portainer
 type portainerDecoratedDockerResource struct {
 	Portainer struct {